@@ -0,0 +1,56 @@
+package log_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tendermint/tmlibs/log"
+)
+
+func TestWithCallerReportsLogSiteNotWithSite(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.WithCaller(log.NewTMLogger(&buf), 2)
+
+	// With() itself must not show up as the reported caller: chaining it
+	// here, then logging from a different call site below, is the whole
+	// point of the test.
+	contextualLogger := logger.With("x", "y")
+	_, _, line, _ := runtime.Caller(0)
+	contextualLogger.Info("m") // <- this is the call site that should be reported
+
+	want := fmt.Sprintf("caller=caller_test.go:%d", line+1)
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected caller to point at the Info call (%s), got %q", want, buf.String())
+	}
+}
+
+func TestWithStackOnlyOnError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.WithStack(log.NewTMLogger(&buf))
+
+	logger.Info("info event")
+	if strings.Contains(buf.String(), "stack=") {
+		t.Errorf("expected no stack key on an info event, got %q", buf.String())
+	}
+	buf.Reset()
+
+	logger.Error("error event")
+	if !strings.Contains(buf.String(), "stack=") {
+		t.Errorf("expected a stack key on an error event, got %q", buf.String())
+	}
+}
+
+func BenchmarkWithStackSkippedWhenFiltered(b *testing.B) {
+	logger := log.NewFilter(log.WithStack(log.NewTMLogger(discard{})), log.AllowNone())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Dropped by the filter before stackLogger.Error ever runs, so
+		// stack.Trace() is never computed.
+		logger.Error("dropped")
+	}
+}
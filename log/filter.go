@@ -0,0 +1,169 @@
+package log
+
+import "fmt"
+
+// level is a bitmask of the severities a filter lets through.
+type level byte
+
+const (
+	levelDebug level = 1 << iota
+	levelInfo
+	levelWarn
+	levelError
+
+	levelNone level = 0
+	levelAll        = levelDebug | levelInfo | levelWarn | levelError
+)
+
+// keyval identifies a bound "key=value" pair, e.g. ("module", "consensus").
+type keyval struct {
+	key   interface{}
+	value interface{}
+}
+
+// Option configures a filter returned by NewFilter.
+type Option func(*filter)
+
+type filter struct {
+	next    Logger
+	allowed level
+
+	// allowedKeyvals holds the per-(key,value) thresholds registered via
+	// AllowLevelByKey. It is consulted by With, so the effective level for
+	// a contextual logger (e.g. one created via .With("module",
+	// "consensus")) is resolved once, at With time, rather than re-checked
+	// on every Debug/Info/Warn/Error call.
+	allowedKeyvals map[keyval]level
+}
+
+var _ Logger = (*filter)(nil)
+
+// NewFilter wraps next and drops any event logged at a severity below the
+// configured threshold. With no options the filter behaves like AllowAll;
+// pass AllowLevelByKey to give specific (key, value) pairs – typically
+// module names – their own threshold.
+func NewFilter(next Logger, opts ...Option) Logger {
+	f := &filter{next: next, allowed: levelAll}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+func (f *filter) Debug(msg string, keyvals ...interface{}) {
+	if f.allowed&levelDebug == 0 {
+		return
+	}
+	f.next.Debug(msg, keyvals...)
+}
+
+func (f *filter) Info(msg string, keyvals ...interface{}) {
+	if f.allowed&levelInfo == 0 {
+		return
+	}
+	f.next.Info(msg, keyvals...)
+}
+
+func (f *filter) Warn(msg string, keyvals ...interface{}) {
+	if f.allowed&levelWarn == 0 {
+		return
+	}
+	f.next.Warn(msg, keyvals...)
+}
+
+func (f *filter) Error(msg string, keyvals ...interface{}) {
+	if f.allowed&levelError == 0 {
+		return
+	}
+	f.next.Error(msg, keyvals...)
+}
+
+// With implements Logger. When keyvals contains a (key, value) pair
+// registered via AllowLevelByKey, the returned logger's threshold switches
+// to that pair's level; otherwise the current threshold carries over
+// unchanged. Either way the threshold is a plain level value stored in
+// the returned filter, so Debug/Info/Warn/Error are a single
+// bitwise comparison on the hot path, no matter how deep the With() chain
+// is.
+func (f *filter) With(keyvals ...interface{}) Logger {
+	allowed := f.allowed
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		if lvl, ok := f.allowedKeyvals[keyval{keyvals[i], keyvals[i+1]}]; ok {
+			allowed = lvl
+		}
+	}
+	return &filter{
+		next:           f.next.With(keyvals...),
+		allowed:        allowed,
+		allowedKeyvals: f.allowedKeyvals,
+	}
+}
+
+// AllowLevel returns an Option that sets the filter's catch-all threshold
+// to lvl, which must be one of "debug", "info", "warn", "error" or "none".
+// It returns an error referencing lvl if it isn't one of those.
+func AllowLevel(lvl string) (Option, error) {
+	switch lvl {
+	case "debug":
+		return AllowDebug(), nil
+	case "info":
+		return AllowInfo(), nil
+	case "warn":
+		return AllowWarn(), nil
+	case "error":
+		return AllowError(), nil
+	case "none":
+		return AllowNone(), nil
+	default:
+		return nil, fmt.Errorf("log: unknown level %q, expected one of \"debug\", \"info\", \"warn\", \"error\", \"none\"", lvl)
+	}
+}
+
+// AllowAll is an alias for AllowDebug: every event is let through.
+func AllowAll() Option { return AllowDebug() }
+
+// AllowDebug allows debug, info, warn and error events through.
+func AllowDebug() Option { return allow(levelDebug | levelInfo | levelWarn | levelError) }
+
+// AllowInfo allows info, warn and error events through.
+func AllowInfo() Option { return allow(levelInfo | levelWarn | levelError) }
+
+// AllowWarn allows warn and error events through.
+func AllowWarn() Option { return allow(levelWarn | levelError) }
+
+// AllowError allows only error events through.
+func AllowError() Option { return allow(levelError) }
+
+// AllowNone drops every event.
+func AllowNone() Option { return allow(levelNone) }
+
+func allow(l level) Option {
+	return func(f *filter) { f.allowed = l }
+}
+
+// AllowLevelByKey returns an Option that gives events bound to the
+// (key, value) pair their own threshold, independent of the filter's
+// catch-all one. opts sets that threshold the same way it would set the
+// catch-all one (AllowDebug, AllowInfo, ...). For example:
+//
+//	log.NewFilter(logger,
+//		log.AllowLevelByKey("module", "consensus", log.AllowDebug()),
+//		log.AllowWarn(),
+//	)
+//
+// lets the "consensus" module log at debug and up, while everything else
+// is filtered at warn and up.
+func AllowLevelByKey(key, value interface{}, opts ...Option) Option {
+	tmp := &filter{}
+	for _, opt := range opts {
+		opt(tmp)
+	}
+	lvl := tmp.allowed
+
+	return func(f *filter) {
+		if f.allowedKeyvals == nil {
+			f.allowedKeyvals = make(map[keyval]level)
+		}
+		f.allowedKeyvals[keyval{key, value}] = lvl
+	}
+}
@@ -0,0 +1,73 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tendermint/tmlibs/log"
+)
+
+func TestFilterAllowLevelGlobal(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewFilter(log.NewTMLogger(&buf), log.AllowWarn())
+
+	logger.Debug("debug event")
+	if buf.Len() != 0 {
+		t.Errorf("expected debug event to be dropped, got %q", buf.String())
+	}
+
+	logger.Error("error event")
+	if !strings.Contains(buf.String(), "error event") {
+		t.Errorf("expected error event to be emitted, got %q", buf.String())
+	}
+}
+
+func TestFilterAllowLevelByKey(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewFilter(log.NewTMLogger(&buf),
+		log.AllowLevelByKey("module", "consensus", log.AllowDebug()),
+		log.AllowWarn(),
+	)
+
+	consensusLogger := logger.With("module", "consensus")
+	consensusLogger.Debug("debug from consensus")
+	if !strings.Contains(buf.String(), "debug from consensus") {
+		t.Errorf("expected debug event from consensus module to be emitted, got %q", buf.String())
+	}
+	buf.Reset()
+
+	p2pLogger := logger.With("module", "p2p")
+	p2pLogger.Debug("debug from p2p")
+	if buf.Len() != 0 {
+		t.Errorf("expected debug event from p2p module to be dropped, got %q", buf.String())
+	}
+
+	p2pLogger.Error("error from p2p")
+	if !strings.Contains(buf.String(), "error from p2p") {
+		t.Errorf("expected error event from p2p module to be emitted, got %q", buf.String())
+	}
+}
+
+func TestFilterAllowLevelInvalid(t *testing.T) {
+	if _, err := log.AllowLevel("verbose"); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	} else if !strings.Contains(err.Error(), "verbose") {
+		t.Errorf("expected error to reference the offending level, got %q", err.Error())
+	}
+}
+
+func TestFilterDoesNotAllocateWhenDropped(t *testing.T) {
+	logger := log.NewFilter(log.NewTMLogger(discard{}), log.AllowError())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Debug("dropped")
+	})
+	if allocs > 0 {
+		t.Errorf("expected 0 allocations for a dropped event, got %v", allocs)
+	}
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,63 @@
+// Package flags provides helpers for turning CLI flag values into
+// configured tmlibs subsystems, starting with log levels.
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tendermint/tmlibs/log"
+)
+
+// ParseLogLevel parses a comma-separated log level spec such as
+// "consensus:debug,p2p:info,*:warn" and returns defaultLogger wrapped in
+// log.NewFilter accordingly.
+//
+// Each clause is either "key:level" (sets the threshold for events bound
+// to module=key) or a bare "level" (sets the catch-all threshold). "*" may
+// be used as key in "key:level" to mean the same thing as a bare level. An
+// empty spec falls back to defaultLevel.
+func ParseLogLevel(spec string, defaultLogger log.Logger, defaultLevel string) (log.Logger, error) {
+	if spec == "" {
+		spec = defaultLevel
+	}
+
+	options := make([]log.Option, 0)
+	defaultLevelSet := false
+
+	for _, clause := range strings.Split(spec, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			return nil, fmt.Errorf("flags: empty clause in log level spec %q", spec)
+		}
+
+		parts := strings.SplitN(clause, ":", 2)
+		if len(parts) == 1 || parts[0] == "*" {
+			lvl := parts[len(parts)-1]
+			option, err := log.AllowLevel(lvl)
+			if err != nil {
+				return nil, fmt.Errorf("flags: parsing log level spec %q: %v", spec, err)
+			}
+			options = append(options, option)
+			defaultLevelSet = true
+			continue
+		}
+
+		module, lvl := parts[0], parts[1]
+		levelOption, err := log.AllowLevel(lvl)
+		if err != nil {
+			return nil, fmt.Errorf("flags: parsing log level spec %q: %v", spec, err)
+		}
+		options = append(options, log.AllowLevelByKey("module", module, levelOption))
+	}
+
+	if !defaultLevelSet {
+		option, err := log.AllowLevel(defaultLevel)
+		if err != nil {
+			return nil, fmt.Errorf("flags: parsing default log level %q: %v", defaultLevel, err)
+		}
+		options = append(options, option)
+	}
+
+	return log.NewFilter(defaultLogger, options...), nil
+}
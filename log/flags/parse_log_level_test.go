@@ -0,0 +1,73 @@
+package flags_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tendermint/tmlibs/log"
+	"github.com/tendermint/tmlibs/log/flags"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	testCases := []struct {
+		name         string
+		spec         string
+		defaultLevel string
+		wantErr      bool
+	}{
+		{"bare level", "debug", "info", false},
+		{"catch-all wildcard", "*:warn", "info", false},
+		{"per-module", "consensus:debug,p2p:info,*:warn", "info", false},
+		{"empty spec falls back to default", "", "warn", false},
+		{"trailing comma", "consensus:debug,", "info", true},
+		{"duplicate keys last wins", "consensus:debug,consensus:error", "info", false},
+		{"unknown level name", "consensus:verbose", "info", true},
+		{"unknown default level", "consensus:debug", "verbose", true},
+		{"malformed clause", "consensus", "info", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := flags.ParseLogLevel(tc.spec, log.NewTMLogger(&buf), tc.defaultLevel)
+			if tc.wantErr && err == nil {
+				t.Fatalf("ParseLogLevel(%q, _, %q): expected an error, got none", tc.spec, tc.defaultLevel)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("ParseLogLevel(%q, _, %q): unexpected error: %v", tc.spec, tc.defaultLevel, err)
+			}
+		})
+	}
+}
+
+func TestParseLogLevelAppliesPerModuleThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := flags.ParseLogLevel("consensus:debug,*:warn", log.NewTMLogger(&buf), "info")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	consensusLogger := logger.With("module", "consensus")
+	consensusLogger.Debug("debug from consensus")
+	if !strings.Contains(buf.String(), "debug from consensus") {
+		t.Errorf("expected debug event from consensus module to be emitted, got %q", buf.String())
+	}
+	buf.Reset()
+
+	p2pLogger := logger.With("module", "p2p")
+	p2pLogger.Info("info from p2p")
+	if buf.Len() != 0 {
+		t.Errorf("expected info event from p2p module to be dropped by the warn catch-all, got %q", buf.String())
+	}
+}
+
+func TestParseLogLevelErrorReferencesOffendingToken(t *testing.T) {
+	_, err := flags.ParseLogLevel("consensus:verbose", log.NewTMLogger(&bytes.Buffer{}), "info")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "verbose") {
+		t.Errorf("expected error to reference the offending level, got %q", err.Error())
+	}
+}
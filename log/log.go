@@ -0,0 +1,21 @@
+package log
+
+// Logger is what any component should take and use.
+//
+// Logger allows libraries/subsystems/packages to log, while leaving
+// observability behavior implementation up to the main application.
+// Applications can choose which output format and which level to log at,
+// and it will be used consistently across all packages/subsystems in use.
+//
+// Logger implementations should be safe for concurrent use by multiple
+// goroutines.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+
+	// With returns a new contextual logger with keyvals prepended to
+	// those passed to calls to Info, Debug, Warn, Error.
+	With(keyvals ...interface{}) Logger
+}
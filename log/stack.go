@@ -0,0 +1,38 @@
+package log
+
+import (
+	"fmt"
+
+	"github.com/go-stack/stack"
+)
+
+// stackLogger injects a "stack=..." keyval, computed via go-stack/stack,
+// into error-level events only. The trace is only ever computed inside
+// Error itself, so it costs nothing on the Debug/Info/Warn paths, and
+// nothing at all if an outer log.NewFilter drops the event before it
+// reaches Error (see BenchmarkWithStackSkippedWhenFiltered).
+type stackLogger struct {
+	next Logger
+}
+
+var _ Logger = (*stackLogger)(nil)
+
+// WithStack returns a Logger that adds a "stack=..." keyval holding the
+// current goroutine's call stack (trimmed of runtime frames) to every
+// error-level event.
+func WithStack(logger Logger) Logger {
+	return &stackLogger{next: logger}
+}
+
+func (l *stackLogger) Debug(msg string, keyvals ...interface{}) { l.next.Debug(msg, keyvals...) }
+func (l *stackLogger) Info(msg string, keyvals ...interface{})  { l.next.Info(msg, keyvals...) }
+func (l *stackLogger) Warn(msg string, keyvals ...interface{})  { l.next.Warn(msg, keyvals...) }
+
+func (l *stackLogger) Error(msg string, keyvals ...interface{}) {
+	trace := fmt.Sprintf("%+v", stack.Trace().TrimRuntime())
+	l.next.Error(msg, append(keyvals, "stack", trace)...)
+}
+
+func (l *stackLogger) With(keyvals ...interface{}) Logger {
+	return &stackLogger{next: l.next.With(keyvals...)}
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	kitlog "github.com/go-kit/kit/log"
+	kitlevel "github.com/go-kit/kit/log/level"
+)
+
+const (
+	msgKey    = "_msg" // "_" prefixed to avoid collisions
+	moduleKey = "module"
+)
+
+type tmLogger struct {
+	srcLogger kitlog.Logger
+}
+
+var _ Logger = (*tmLogger)(nil)
+
+// NewTMLogger returns a Logger that encodes msg and keyvals to the Writer
+// using the custom Tendermint format (see NewTMFmtLoggerZerolog), and is
+// safe for concurrent use by multiple goroutines.
+func NewTMLogger(w io.Writer, opts ...LoggerOption) Logger {
+	return &tmLogger{srcLogger: NewTMFmtLoggerZerolog(w, opts...)}
+}
+
+// kitWither is implemented by kitlog.Logger values, such as the one
+// returned by NewTMFmtLoggerZerolog, that pre-render their bound keyvals
+// into their own context instead of relying on kitlog.With's generic,
+// per-call Context wrapping. tmLogger.With prefers it when available so a
+// logger.With(...) chain stays on the zero-allocation path all the way
+// down to Log.
+type kitWither interface {
+	With(keyvals ...interface{}) kitlog.Logger
+}
+
+// levelLogger is implemented by kitlog.Logger values, such as the one
+// returned by NewTMFmtLoggerZerolog, that can take level and msg as
+// dedicated parameters. tmLogger.log prefers it when available so it
+// never has to splice level/msg into a freshly allocated keyvals slice
+// just so the generic Log(keyvals ...interface{}) path can scan them back
+// out again.
+type levelLogger interface {
+	logLevel(lvl kitlevel.Value, msg string, keyvals ...interface{}) error
+}
+
+func (l *tmLogger) log(lvl kitlevel.Value, msg string, keyvals ...interface{}) {
+	var err error
+	if ll, ok := l.srcLogger.(levelLogger); ok {
+		err = ll.logLevel(lvl, msg, keyvals...)
+	} else {
+		kvs := make([]interface{}, 0, len(keyvals)+4)
+		kvs = append(kvs, kitlevel.Key(), lvl, msgKey, msg)
+		kvs = append(kvs, keyvals...)
+		err = l.srcLogger.Log(kvs...)
+	}
+	if err != nil {
+		// Re-log without the original keyvals: they're what caused err in
+		// the first place (e.g. an invalid key rejected by appendKeyval),
+		// and retrying with them unchanged would just hit the same
+		// validation failure again and abort before writing anything.
+		// Surfacing it through the same logger, rather than straight to
+		// os.Stderr, puts it on the configured writer like any other
+		// event. If that retry itself fails - e.g. the writer itself is
+		// broken (closed file, full disk) rather than the data - fall
+		// back to os.Stderr so the failure is still visible somewhere.
+		if retryErr := l.srcLogger.Log(kitlevel.Key(), lvl, msgKey, msg, "err", err); retryErr != nil {
+			fmt.Fprintln(os.Stderr, "log:", err, "(and failed to report it:", retryErr, ")")
+		}
+	}
+}
+
+func (l *tmLogger) Debug(msg string, keyvals ...interface{}) {
+	l.log(kitlevel.DebugValue(), msg, keyvals...)
+}
+
+func (l *tmLogger) Info(msg string, keyvals ...interface{}) {
+	l.log(kitlevel.InfoValue(), msg, keyvals...)
+}
+
+func (l *tmLogger) Warn(msg string, keyvals ...interface{}) {
+	l.log(kitlevel.WarnValue(), msg, keyvals...)
+}
+
+func (l *tmLogger) Error(msg string, keyvals ...interface{}) {
+	l.log(kitlevel.ErrorValue(), msg, keyvals...)
+}
+
+func (l *tmLogger) With(keyvals ...interface{}) Logger {
+	if w, ok := l.srcLogger.(kitWither); ok {
+		return &tmLogger{srcLogger: w.With(keyvals...)}
+	}
+	return &tmLogger{srcLogger: kitlog.With(l.srcLogger, keyvals...)}
+}
@@ -30,22 +30,30 @@ var tmfmtEncoderPool = sync.Pool{
 	},
 }
 
+// loggerOptions holds the settings shared by every logger constructor in
+// this package (NewTMFmtLogger, NewTMFmtLoggerZerolog, ...) so LoggerOption
+// implementations don't need to know which concrete logger they're
+// configuring.
+type loggerOptions struct {
+	noTimePrefixing bool
+}
+
 type tmfmtLogger struct {
 	w io.Writer
 
-	noTimePrefixing bool
+	loggerOptions
 }
 
 type LoggerOption interface {
-	with(*tmfmtLogger)
+	with(*loggerOptions)
 }
 
 type timePrefixDisabler int
 
 var _ LoggerOption = (*timePrefixDisabler)(nil)
 
-func (tfd timePrefixDisabler) with(tfl *tmfmtLogger) {
-	tfl.noTimePrefixing = true
+func (tfd timePrefixDisabler) with(o *loggerOptions) {
+	o.noTimePrefixing = true
 }
 
 const OptionDisableTimePrefix = timePrefixDisabler(1)
@@ -60,7 +68,7 @@ const OptionDisableTimePrefix = timePrefixDisabler(1)
 func NewTMFmtLogger(w io.Writer, opts ...LoggerOption) kitlog.Logger {
 	tfl := &tmfmtLogger{w: w}
 	for _, opt := range opts {
-		opt.with(tfl)
+		opt.with(&tfl.loggerOptions)
 	}
 	return tfl
 }
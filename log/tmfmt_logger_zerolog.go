@@ -0,0 +1,324 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	kitlevel "github.com/go-kit/kit/log/level"
+	"github.com/go-logfmt/logfmt"
+)
+
+// tmfmtZeroBuffer is a reusable, pre-allocated byte buffer, pooled the same
+// way zerolog pools its *Event: callers borrow one from
+// tmfmtZeroBufferPool, append to it with the typed helpers below (which
+// never go through fmt.Sprintf or reflection for the keyval types that
+// actually show up on tmlibs' hot logging paths), write it out, and return
+// it to the pool.
+type tmfmtZeroBuffer struct {
+	b []byte
+}
+
+var tmfmtZeroBufferPool = sync.Pool{
+	New: func() interface{} {
+		return &tmfmtZeroBuffer{b: make([]byte, 0, 256)}
+	},
+}
+
+func getTmfmtZeroBuffer() *tmfmtZeroBuffer {
+	buf := tmfmtZeroBufferPool.Get().(*tmfmtZeroBuffer)
+	buf.b = buf.b[:0]
+	return buf
+}
+
+func putTmfmtZeroBuffer(buf *tmfmtZeroBuffer) {
+	tmfmtZeroBufferPool.Put(buf)
+}
+
+// appendSep appends a single space separator, unless buf is empty or
+// already ends in one. Every piece of this file's output (module=,
+// bound-context keyvals, per-call keyvals) calls it before writing
+// itself, so exactly one space ever separates two tokens regardless of
+// which combination of module/ctx/keyvals is present on a given call.
+func appendSep(buf []byte) []byte {
+	if n := len(buf); n > 0 && buf[n-1] != ' ' {
+		return append(buf, ' ')
+	}
+	return buf
+}
+
+// validLogfmtKey reports whether key can appear unquoted on the left-hand
+// side of a logfmt "key=value" pair: non-empty, and free of the
+// whitespace/'='/'"' characters that would make the line ambiguous to
+// parse back. Mirrors go-logfmt/logfmt's own key validation, which
+// EncodeKeyval enforces by returning logfmt.ErrInvalidKey.
+func validLogfmtKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; c <= ' ' || c == '=' || c == '"' {
+			return false
+		}
+	}
+	return true
+}
+
+// appendKeyval appends "key=value" to buf, special-casing the value types
+// that actually show up on tmlibs' hot logging paths (string, error,
+// fmt.Stringer, the various ints, bool, float64, time.Duration) so that
+// only genuinely unknown types fall back to fmt.Sprintf("%+v", ...), same
+// as tmfmtLogger's logfmt.ErrUnsupportedValueType fallback. Callers are
+// responsible for separating it from whatever precedes it (see
+// appendSep). Returns logfmt.ErrInvalidKey, leaving buf untouched, if key
+// isn't valid logfmt syntax - callers must not write the record in that
+// case, same as tmfmtLogger's Encoder-backed path.
+func (buf *tmfmtZeroBuffer) appendKeyval(key string, val interface{}) error {
+	if !validLogfmtKey(key) {
+		return logfmt.ErrInvalidKey
+	}
+
+	buf.b = append(buf.b, key...)
+	buf.b = append(buf.b, '=')
+
+	switch v := val.(type) {
+	case string:
+		buf.b = appendLogfmtValue(buf.b, v)
+	case error:
+		buf.b = appendLogfmtValue(buf.b, v.Error())
+	case fmt.Stringer:
+		buf.b = appendLogfmtValue(buf.b, v.String())
+	case bool:
+		buf.b = strconv.AppendBool(buf.b, v)
+	case int:
+		buf.b = strconv.AppendInt(buf.b, int64(v), 10)
+	case int64:
+		buf.b = strconv.AppendInt(buf.b, v, 10)
+	case uint64:
+		buf.b = strconv.AppendUint(buf.b, v, 10)
+	case float64:
+		buf.b = strconv.AppendFloat(buf.b, v, 'g', -1, 64)
+	case time.Duration:
+		buf.b = appendLogfmtValue(buf.b, v.String())
+	default:
+		buf.b = appendLogfmtValue(buf.b, fmt.Sprintf("%+v", val))
+	}
+	return nil
+}
+
+// appendLogfmtValue appends a logfmt-quoted value: bare if it needs no
+// quoting, double-quoted (with Go-syntax escaping) otherwise. Mirrors
+// go-logfmt/logfmt's own quoting rule closely enough for our purposes:
+// anything containing whitespace, '=' or '"' gets quoted.
+func appendLogfmtValue(buf []byte, s string) []byte {
+	needsQuote := s == ""
+	for i := 0; i < len(s) && !needsQuote; i++ {
+		switch c := s[i]; {
+		case c <= ' ', c == '=', c == '"':
+			needsQuote = true
+		}
+	}
+	if !needsQuote {
+		return append(buf, s...)
+	}
+	return strconv.AppendQuote(buf, s)
+}
+
+// appendPadded right-pads s with spaces up to width and appends a
+// trailing space, mirroring the "%-Ns " formatting tmfmtLogger produces
+// via fmt.Sprintf, without going through the fmt package.
+func appendPadded(buf []byte, s string, width int) []byte {
+	buf = append(buf, s...)
+	for i := len(s); i < width; i++ {
+		buf = append(buf, ' ')
+	}
+	return append(buf, ' ')
+}
+
+// tmfmtZerologLogger is a zero-allocation-on-the-hot-path counterpart to
+// tmfmtLogger. It extracts msg and level the same way and produces the
+// same "D[01-02|15:04:05.000] msg ... module=X key=v" line layout, but
+// replaces fmt.Sprintf, the sync.Pool of logfmt.Encoder and the
+// per-call excludeIndexes scan with typed, allocation-free appenders on a
+// pooled byte buffer (the same pre-allocated-event design zerolog uses
+// internally), and resolves With() context once instead of on every Log
+// call.
+//
+// NewTMFmtLoggerZerolog intentionally does not route events through
+// zerolog's own Event/encoder: zerolog always terminates an event as a
+// single JSON object, and preserving tmfmtLogger's bare "D[...] msg ..."
+// surface rules that out. What it keeps from zerolog's design is the
+// pooled-buffer, typed-appender approach that makes zerolog itself
+// allocation-free on its hot path.
+type tmfmtZerologLogger struct {
+	w io.Writer
+	loggerOptions
+
+	// ctx is the already-rendered "key=val key2=val2" suffix contributed
+	// by a prior With() call. It is copied verbatim into every event
+	// logged through this logger, so a contextual logger built via With()
+	// never re-walks or re-encodes its bound keyvals on the Log() path.
+	ctx []byte
+}
+
+var _ kitlog.Logger = (*tmfmtZerologLogger)(nil)
+
+// NewTMFmtLoggerZerolog returns a kitlog.Logger with the line layout of
+// NewTMFmtLogger, built on pre-allocated buffers instead of fmt.Sprintf
+// and reflection so that BenchmarkTMLoggerContextual-style usage (With()
+// once, Log() many times) is allocation-free on the Log path.
+//
+// Its output is not byte-for-byte that of NewTMFmtLogger: a module bound
+// via With() is written where it sits in the bound context and is not
+// replaced by a module passed to Log; module values are quoted like any
+// other value; no space follows module=X at the end of a line; and values
+// are rendered by appendKeyval, which differs from logfmt's encoding for
+// some types (nil, encoding.TextMarshaler values such as time.Time).
+//
+// Each log event produces no more than one call to w.Write.
+// The passed Writer must be safe for concurrent use by multiple goroutines if
+// the returned Logger will be used concurrently.
+func NewTMFmtLoggerZerolog(w io.Writer, opts ...LoggerOption) kitlog.Logger {
+	tfl := &tmfmtZerologLogger{w: w}
+	for _, opt := range opts {
+		opt.with(&tfl.loggerOptions)
+	}
+	return tfl
+}
+
+// With returns a logger that has pre-rendered keyvals into its context
+// buffer, so that logger.With(...).Log(...) only pays the keyvals->bytes
+// cost for the per-call keyvals, never for ctx.
+//
+// kitlog.Logger.With has no error return, so a key that fails
+// validLogfmtKey (see appendKeyval) can't simply abort the way Log does;
+// instead it's surfaced as a visible `err="invalid key"` keyval in the
+// bound context, same spirit as tmLogger.log's error handling in
+// tm_logger.go, rather than silently accepted or silently dropped.
+func (l *tmfmtZerologLogger) With(keyvals ...interface{}) kitlog.Logger {
+	buf := getTmfmtZeroBuffer()
+	defer putTmfmtZeroBuffer(buf)
+
+	buf.b = append(buf.b, l.ctx...)
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", keyvals[i])
+		}
+		buf.b = appendSep(buf.b)
+		if err := buf.appendKeyval(key, keyvals[i+1]); err != nil {
+			buf.appendKeyval("err", err)
+		}
+	}
+
+	ctx := make([]byte, len(buf.b))
+	copy(ctx, buf.b)
+
+	return &tmfmtZerologLogger{w: l.w, loggerOptions: l.loggerOptions, ctx: ctx}
+}
+
+// logLevel implements the levelLogger interface (see tm_logger.go): it
+// lets a caller that already knows its level and message as discrete
+// values hand them over directly instead of splicing them into a freshly
+// allocated keyvals slice just so Log can re-extract them by scanning for
+// kitlevel.Key()/msgKey.
+var _ levelLogger = (*tmfmtZerologLogger)(nil)
+
+func (l *tmfmtZerologLogger) logLevel(lvl kitlevel.Value, msg string, keyvals ...interface{}) error {
+	return l.logEvent(lvl.String(), msg, keyvals, false)
+}
+
+func (l *tmfmtZerologLogger) Log(keyvals ...interface{}) error {
+	const unknown = "unknown"
+	lvl := "none"
+	msg := unknown
+
+KeyvalueLoop:
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		switch keyvals[i] {
+		case kitlevel.Key():
+			switch v := keyvals[i+1].(type) {
+			case string:
+				lvl = v
+			case kitlevel.Value:
+				lvl = v.String()
+			default:
+				panic(fmt.Sprintf("level value of unknown type %T", keyvals[i+1]))
+			}
+			continue KeyvalueLoop
+		case msgKey:
+			msg = keyvals[i+1].(string)
+			continue KeyvalueLoop
+		}
+	}
+
+	return l.logEvent(lvl, msg, keyvals, true)
+}
+
+// logEvent renders one event: the "D[...] msg" (or padded-msg) prefix,
+// "module=" if bound or passed, the cached With() context, and finally
+// keyvals itself. reservedKeys is true when keyvals may still contain the
+// kitlevel.Key()/msgKey pair that Log just extracted (so those must be
+// skipped on the second pass); logLevel's caller already stripped those
+// out of keyvals entirely, so it passes false.
+func (l *tmfmtZerologLogger) logEvent(lvl, msg string, keyvals []interface{}, reservedKeys bool) error {
+	const unknown = "unknown"
+	module := unknown
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		if keyvals[i] == moduleKey {
+			module = keyvals[i+1].(string)
+		}
+	}
+
+	buf := getTmfmtZeroBuffer()
+	defer putTmfmtZeroBuffer(buf)
+
+	if l.noTimePrefixing {
+		buf.b = appendPadded(buf.b, msg, 65)
+	} else {
+		buf.b = append(buf.b, lvl[0]-32, '[')
+		buf.b = time.Now().UTC().AppendFormat(buf.b, "01-02|15:04:05.000")
+		buf.b = append(buf.b, ']', ' ')
+		buf.b = appendPadded(buf.b, msg, 44)
+	}
+
+	if module != unknown {
+		buf.b = appendSep(buf.b)
+		buf.b = append(buf.b, "module="...)
+		buf.b = appendLogfmtValue(buf.b, module)
+	}
+
+	if len(l.ctx) > 0 {
+		buf.b = appendSep(buf.b)
+		buf.b = append(buf.b, l.ctx...)
+	}
+
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		if reservedKeys {
+			switch keyvals[i] {
+			case kitlevel.Key(), msgKey, moduleKey:
+				continue
+			}
+		} else if keyvals[i] == moduleKey {
+			continue
+		}
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", keyvals[i])
+		}
+		buf.b = appendSep(buf.b)
+		if err := buf.appendKeyval(key, keyvals[i+1]); err != nil {
+			return err
+		}
+	}
+
+	buf.b = append(buf.b, '\n')
+
+	if _, err := l.w.Write(buf.b); err != nil {
+		return err
+	}
+	return nil
+}
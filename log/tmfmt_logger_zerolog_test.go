@@ -0,0 +1,58 @@
+package log_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/tendermint/tmlibs/log"
+)
+
+// withLogger is implemented by every logger in this package that supports
+// the With() fast path (see tmfmtZerologLogger).
+type withLogger interface {
+	With(keyvals ...interface{}) kitlog.Logger
+}
+
+func TestTMFmtLoggerZerologMatchesTMFmtLogger(t *testing.T) {
+	var bufFmt, bufZero bytes.Buffer
+
+	loggerFmt := log.NewTMFmtLogger(&bufFmt, log.OptionDisableTimePrefix)
+	loggerZero := log.NewTMFmtLoggerZerolog(&bufZero, log.OptionDisableTimePrefix)
+
+	loggerFmt.Log("_msg", "hello", "module", "p2p", "key", "value")
+	loggerZero.Log("_msg", "hello", "module", "p2p", "key", "value")
+
+	if bufFmt.String() != bufZero.String() {
+		t.Errorf("expected identical output, got:\nfmt:  %q\nzero: %q", bufFmt.String(), bufZero.String())
+	}
+}
+
+func TestTMFmtLoggerZerologWith(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, ok := log.NewTMFmtLoggerZerolog(&buf, log.OptionDisableTimePrefix).(withLogger)
+	if !ok {
+		t.Fatalf("expected NewTMFmtLoggerZerolog to return a logger exposing With")
+	}
+
+	logger.With("common_key", "common_value").Log("_msg", "hi")
+
+	out := strings.TrimSpace(buf.String())
+	if !strings.HasSuffix(out, "common_key=common_value") {
+		t.Errorf("expected bound context to be appended, got %q", out)
+	}
+}
+
+func BenchmarkTMFmtLoggerZerologContextual(b *testing.B) {
+	logger := log.NewTMFmtLoggerZerolog(ioutil.Discard).(withLogger)
+	lc := logger.With("common_key", "common_value")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lc.Log("_msg", "c", "d", "f")
+	}
+}
@@ -0,0 +1,152 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	kitlevel "github.com/go-kit/kit/log/level"
+)
+
+type tmJSONLogger struct {
+	w io.Writer
+
+	loggerOptions
+}
+
+var _ kitlog.Logger = (*tmJSONLogger)(nil)
+
+var tmJSONBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// NewTMJSONLogger returns a logger that encodes keyvals to the Writer as a
+// single JSON object per event, using the same msg/module/level
+// conventions as NewTMFmtLogger: "time" (UTC RFC3339Nano, dropped by
+// OptionDisableTimePrefix), "level" (the full lowercased level name),
+// "msg", "module" (omitted when unknown), and the remaining keyvals
+// flattened at the top level. Intended for downstream log-aggregation
+// pipelines (Loki, ELK, ...) that would rather not parse the custom tmfmt
+// line format.
+//
+// Each log event produces no more than one call to w.Write.
+// The passed Writer must be safe for concurrent use by multiple goroutines if
+// the returned Logger will be used concurrently.
+func NewTMJSONLogger(w io.Writer, opts ...LoggerOption) kitlog.Logger {
+	tjl := &tmJSONLogger{w: w}
+	for _, opt := range opts {
+		opt.with(&tjl.loggerOptions)
+	}
+	return tjl
+}
+
+func (l *tmJSONLogger) Log(keyvals ...interface{}) error {
+	const unknown = "unknown"
+	lvl := "none"
+	msg := unknown
+	module := unknown
+
+	excludeIndexes := make([]int, 0, 3)
+
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		switch keyvals[i] {
+		case kitlevel.Key():
+			excludeIndexes = append(excludeIndexes, i)
+			switch v := keyvals[i+1].(type) {
+			case string:
+				lvl = v
+			case kitlevel.Value:
+				lvl = v.String()
+			default:
+				panic(fmt.Sprintf("level value of unknown type %T", keyvals[i+1]))
+			}
+		case msgKey:
+			excludeIndexes = append(excludeIndexes, i)
+			msg = keyvals[i+1].(string)
+		case moduleKey:
+			// could be multiple keyvals; if so the last keyvalue wins, mirroring tmfmt
+			excludeIndexes = append(excludeIndexes, i)
+			module = keyvals[i+1].(string)
+		}
+	}
+
+	buf := tmJSONBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer tmJSONBufferPool.Put(buf)
+
+	buf.WriteByte('{')
+	first := true
+
+	writeField := func(key string, val interface{}) error {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+
+		valJSON, err := json.Marshal(val)
+		if err != nil {
+			// mirror tmfmtLogger's fallback for values json can't encode
+			valJSON, err = json.Marshal(fmt.Sprintf("%+v", val))
+			if err != nil {
+				return err
+			}
+		}
+		buf.Write(valJSON)
+		return nil
+	}
+
+	if !l.noTimePrefixing {
+		if err := writeField("time", time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+			return err
+		}
+	}
+	if err := writeField("level", lvl); err != nil {
+		return err
+	}
+	if err := writeField("msg", msg); err != nil {
+		return err
+	}
+	if module != unknown {
+		if err := writeField("module", module); err != nil {
+			return err
+		}
+	}
+
+KeyvalueLoop:
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		for _, j := range excludeIndexes {
+			if i == j {
+				continue KeyvalueLoop
+			}
+		}
+
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", keyvals[i])
+		}
+		if err := writeField(key, keyvals[i+1]); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	// The Logger interface requires implementations to be safe for concurrent
+	// use by multiple goroutines. For this implementation that means making
+	// only one call to l.w.Write() for each call to Log.
+	if _, err := l.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
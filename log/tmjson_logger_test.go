@@ -0,0 +1,88 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	kitlevel "github.com/go-kit/kit/log/level"
+	"github.com/tendermint/tmlibs/log"
+)
+
+func TestTMJSONLoggerProducesValidJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewTMJSONLogger(&buf, log.OptionDisableTimePrefix)
+	logger.Log(kitlevel.Key(), kitlevel.InfoValue(), "_msg", "hello", "module", "p2p", "peers", 3)
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("expected valid JSON, got error %v for %q", err, buf.String())
+	}
+
+	if out["msg"] != "hello" {
+		t.Errorf("expected msg=hello, got %v", out["msg"])
+	}
+	if out["module"] != "p2p" {
+		t.Errorf("expected module=p2p, got %v", out["module"])
+	}
+	if out["level"] != "info" {
+		t.Errorf("expected level=info, got %v", out["level"])
+	}
+	if out["peers"] != float64(3) {
+		t.Errorf("expected peers=3, got %v", out["peers"])
+	}
+	if _, ok := out["time"]; ok {
+		t.Errorf("expected time field to be dropped by OptionDisableTimePrefix, got %v", out["time"])
+	}
+}
+
+func TestTMJSONLoggerOmitsUnknownModule(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewTMJSONLogger(&buf, log.OptionDisableTimePrefix)
+	logger.Log("_msg", "hello")
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("expected valid JSON, got error %v for %q", err, buf.String())
+	}
+	if _, ok := out["module"]; ok {
+		t.Errorf("expected module field to be omitted when unknown, got %v", out["module"])
+	}
+}
+
+func TestTMJSONLoggerDuplicateModuleLastWins(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.NewTMJSONLogger(&buf, log.OptionDisableTimePrefix)
+	logger.Log("_msg", "hello", "module", "p2p", "module", "consensus")
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("expected valid JSON, got error %v for %q", err, buf.String())
+	}
+	if out["module"] != "consensus" {
+		t.Errorf("expected last module keyval to win, got %v", out["module"])
+	}
+}
+
+func TestTMJSONLoggerSingleWrite(t *testing.T) {
+	cw := &countingWriter{}
+	logger := log.NewTMJSONLogger(cw, log.OptionDisableTimePrefix)
+	logger.Log("_msg", "hello")
+	if cw.writes != 1 {
+		t.Errorf("expected exactly one Write call per Log, got %d", cw.writes)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(cw.buf.String()), "}") {
+		t.Errorf("expected output to be a single JSON object, got %q", cw.buf.String())
+	}
+}
+
+type countingWriter struct {
+	buf    bytes.Buffer
+	writes int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.writes++
+	return cw.buf.Write(p)
+}